@@ -0,0 +1,115 @@
+/*
+Copyright © 2022 Open Traffic Generator
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions://
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// cfgFile is the config file to read named profiles from, defaulting to
+// $HOME/.otgen.yaml. profile selects which `profiles.<name>` section of
+// that file supplies flag defaults.
+var cfgFile string
+var profile string
+
+// envAliases maps a flag name to additional environment variables checked
+// after the flag's automatic OTG_<FLAG NAME> lookup, for flags whose
+// long-established env var name doesn't match the flag name verbatim.
+var envAliases = map[string][]string{
+	"interval": {"OTG_PULL_INTERVAL"},
+}
+
+func init() {
+	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Config file to read named profiles from (default is $HOME/.otgen.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Named profile from the config file to use for flag defaults, e.g. --profile lab1")
+}
+
+// initConfig locates the otgen config file so BindCommandFlags can read
+// profile sections out of it. A missing config file is not an error: every
+// flag still has its built-in default.
+func initConfig() {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		home, err := homedir.Dir()
+		cobra.CheckErr(err)
+		viper.AddConfigPath(home)
+		viper.SetConfigType("yaml")
+		viper.SetConfigName(".otgen")
+	}
+
+	_ = viper.ReadInConfig()
+}
+
+// BindCommandFlags resolves every not-explicitly-set flag on cmd from, in
+// order of precedence: the CLI flag itself, an OTG_<FLAG NAME> environment
+// variable (or one of envAliases), the selected --profile section of the
+// config file, and finally the flag's own built-in default (left
+// untouched). Call this once Cobra has parsed flags, before reading them.
+func BindCommandFlags(cmd *cobra.Command) error {
+	v := viper.New()
+	if cfgFile := viper.ConfigFileUsed(); cfgFile != "" {
+		v.SetConfigFile(cfgFile)
+		_ = v.ReadInConfig()
+	}
+
+	var firstErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed || firstErr != nil {
+			return
+		}
+
+		if val, ok := lookupEnv(f.Name); ok {
+			firstErr = cmd.Flags().Set(f.Name, val)
+			return
+		}
+
+		if profile != "" {
+			key := fmt.Sprintf("profiles.%s.%s", profile, f.Name)
+			if v.IsSet(key) {
+				firstErr = cmd.Flags().Set(f.Name, v.GetString(key))
+			}
+		}
+	})
+
+	return firstErr
+}
+
+// lookupEnv checks OTG_<FLAG NAME> and any aliases registered for flagName,
+// in that order.
+func lookupEnv(flagName string) (string, bool) {
+	names := append([]string{"OTG_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))}, envAliases[flagName]...)
+	for _, name := range names {
+		if val, ok := os.LookupEnv(name); ok {
+			return val, true
+		}
+	}
+	return "", false
+}
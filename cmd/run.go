@@ -22,15 +22,23 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/open-traffic-generator/otgen/internal/metricsink"
+	"github.com/open-traffic-generator/otgen/internal/retry"
+	"github.com/open-traffic-generator/otgen/internal/tracing"
 	"github.com/open-traffic-generator/snappi/gosnappi"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var otgURL string                 // URL of OTG server API endpoint
@@ -43,6 +51,30 @@ var otgPullIntervalStr string     // Interval to pull OTG metrics. Example: 1s (
 var otgPullInterval time.Duration // Parsed interval to pull OTG metrics
 var xeta = float32(0.0)           // How long to wait before forcing traffic to stop. In multiples of ETA
 
+var otgSink string        // Comma-separated list of sinks to report metrics to: stdout, otlp, report
+var otlpEndpoint string   // OTLP collector endpoint, e.g. localhost:4317
+var otlpProtocol string   // OTLP wire protocol: grpc or http/protobuf
+var otlpInsecure bool     // Skip TLS verification when talking to the OTLP collector
+var otlpHeadersStr string // Extra OTLP headers as key=val,key2=val2
+
+var otgTransport string     // Transport to use to reach the OTG API endpoint: http or grpc
+var grpcTimeoutStr string   // Timeout for individual gRPC requests to the OTG API endpoint. Example: 30s
+var grpcMaxMsgSize int      // Maximum gRPC message size in bytes the client will accept from the OTG API endpoint
+var grpcKeepaliveStr string // Interval between gRPC keep-alive pings to the OTG API endpoint. Example: 10s
+
+var otelTracesEndpoint string // OTLP traces collector endpoint. Falls back to OTEL_EXPORTER_OTLP_*_ENDPOINT when unset
+var otelTracesProtocol string // OTLP traces wire protocol: grpc or http/protobuf
+
+var retryMaxElapsedStr string   // Total time to keep retrying a transient OTG API error before giving up. Example: 30s
+var retryInitIntervalStr string // Delay before the first retry of a transient OTG API error. Example: 200ms
+var retryMultiplier float64     // Factor the retry delay grows by after each attempt
+var retryOnStr string           // Comma-separated error classes to retry: deadline, unavailable, connrefused
+
+var reportFormat string           // Wire format for the "report" sink's flow-metric batches: json, ndjson, or arrow
+var reportOutput string           // Destination for the "report" sink: -, file://path, or grpc://host:port
+var reportBatchSize int           // Flush a report batch once this many changed flow records have accumulated
+var reportBatchIntervalStr string // Flush a report batch at least this often, even below --batch-size. Example: 5s
+
 // Create a new instance of the logger
 var log = logrus.New()
 
@@ -55,6 +87,10 @@ var runCmd = &cobra.Command{
 For more information, go to https://github.com/open-traffic-generator/otgen
 `,
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := BindCommandFlags(cmd); err != nil {
+			log.Fatal(err)
+		}
+
 		switch otgMetrics {
 		case "port":
 		case "flow":
@@ -68,7 +104,24 @@ For more information, go to https://github.com/open-traffic-generator/otgen
 			log.Fatal(err)
 		}
 
-		runTraffic(initOTG())
+		ctx := context.Background()
+
+		sink, err := initSinks(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer sink.Close(ctx)
+
+		shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+			Endpoint: otelTracesEndpoint,
+			Protocol: otelTracesProtocol,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer shutdownTracing(ctx)
+
+		runTraffic(ctx, initOTG(), sink)
 	},
 }
 
@@ -93,6 +146,114 @@ func init() {
 	runCmd.Flags().StringVarP(&otgMetrics, "metrics", "m", "port", "Metrics type to report:\n  \"port\" for PortMetrics,\n  \"flow\" for FlowMetrics\n ")
 	runCmd.Flags().StringVarP(&otgPullIntervalStr, "interval", "i", "0.5s", "Interval to pull OTG metrics. Valid time units are 'ms', 's', 'm', 'h'. Example: 1s")
 	runCmd.Flags().Float32VarP(&xeta, "xeta", "x", float32(0.0), "How long to wait before forcing traffic to stop. In multiples of ETA. Example: 1.5 (default is no limit)")
+
+	runCmd.Flags().StringVar(&otgSink, "sink", "stdout", "Comma-separated list of destinations to report metrics to: stdout, otlp, report. Example: --sink=stdout,otlp")
+	runCmd.Flags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "OTLP collector endpoint to export metrics to, e.g. localhost:4317. Required when --sink includes otlp")
+	runCmd.Flags().StringVar(&otlpProtocol, "otlp-protocol", "grpc", "OTLP wire protocol to use: grpc or http/protobuf")
+	runCmd.Flags().BoolVar(&otlpInsecure, "otlp-insecure", false, "Skip TLS verification when talking to the OTLP collector")
+	runCmd.Flags().StringVar(&otlpHeadersStr, "otlp-headers", "", "Extra headers to send with every OTLP export, as key=val,key2=val2")
+
+	runCmd.Flags().StringVar(&otgTransport, "transport", "", "Transport to use to reach the OTG API endpoint: http or grpc. Defaults to grpc when --api uses a grpc:// scheme, http otherwise")
+	runCmd.Flags().StringVar(&grpcTimeoutStr, "grpc-timeout", "30s", "Timeout for individual gRPC requests to the OTG API endpoint. Example: 30s")
+	runCmd.Flags().IntVar(&grpcMaxMsgSize, "grpc-max-msg-size", 0, "Maximum gRPC message size in bytes the client will accept from the OTG API endpoint. 0 uses the gRPC client default")
+	runCmd.Flags().StringVar(&grpcKeepaliveStr, "grpc-keepalive", "", "Interval between gRPC keep-alive pings to the OTG API endpoint. Example: 10s. Empty disables keep-alive pings")
+
+	runCmd.Flags().StringVar(&otelTracesEndpoint, "otel-traces-endpoint", "", "OTLP traces collector endpoint to export run spans to. Falls back to OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_EXPORTER_OTLP_TRACES_ENDPOINT when unset")
+	runCmd.Flags().StringVar(&otelTracesProtocol, "otel-traces-protocol", "", "OTLP traces wire protocol to use: grpc or http/protobuf. Falls back to OTEL_EXPORTER_OTLP_TRACES_PROTOCOL / OTEL_EXPORTER_OTLP_PROTOCOL, then grpc, when unset")
+
+	runCmd.Flags().StringVar(&retryMaxElapsedStr, "retry-max-elapsed", "30s", "Total time to keep retrying a transient OTG API error before giving up. Example: 30s")
+	runCmd.Flags().StringVar(&retryInitIntervalStr, "retry-initial-interval", "200ms", "Delay before the first retry of a transient OTG API error. Example: 200ms")
+	runCmd.Flags().Float64Var(&retryMultiplier, "retry-multiplier", 1.5, "Factor the retry delay grows by after each attempt")
+	runCmd.Flags().StringVar(&retryOnStr, "retry-on", "deadline,unavailable,connrefused", "Comma-separated error classes to retry instead of failing the run: deadline, unavailable, connrefused")
+
+	runCmd.Flags().StringVar(&reportFormat, "report-format", "ndjson", "Wire format for the \"report\" sink's flow-metric batches: json, ndjson, or arrow")
+	runCmd.Flags().StringVar(&reportOutput, "report-output", "-", "Destination for the \"report\" sink: - for stdout, file://path, or grpc://host:port")
+	runCmd.Flags().IntVar(&reportBatchSize, "batch-size", 1000, "Flush a report batch once this many changed flow records have accumulated")
+	runCmd.Flags().StringVar(&reportBatchIntervalStr, "batch-interval", "5s", "Flush a report batch at least this often, even below --batch-size. Example: 5s")
+}
+
+// retryConfig parses the --retry-* flags into a retry.Config.
+func retryConfig() (retry.Config, error) {
+	maxElapsed, err := time.ParseDuration(retryMaxElapsedStr)
+	if err != nil {
+		return retry.Config{}, err
+	}
+	initInterval, err := time.ParseDuration(retryInitIntervalStr)
+	if err != nil {
+		return retry.Config{}, err
+	}
+	return retry.Config{
+		MaxElapsedTime:  maxElapsed,
+		InitialInterval: initInterval,
+		Multiplier:      retryMultiplier,
+		RetryOn:         strings.Split(retryOnStr, ","),
+	}, nil
+}
+
+// withRetry runs fn with the configured retry policy, logging each retry
+// attempt at Warn level. It returns an error at once for errors outside
+// --retry-on, and for retryable errors only once the retry budget is
+// exhausted.
+func withRetry(ctx context.Context, fn func() error) error {
+	cfg, err := retryConfig()
+	if err != nil {
+		return err
+	}
+	return retry.Do(ctx, cfg, fn, func(attempt int, err error) {
+		log.Warnf("Retrying OTG API call (attempt %d) after error: %v", attempt, err)
+	})
+}
+
+// initSinks builds the fan-out metrics sink requested via --sink.
+func initSinks(ctx context.Context) (*metricsink.Multi, error) {
+	var sinks []metricsink.Sink
+
+	for _, name := range strings.Split(otgSink, ",") {
+		switch strings.TrimSpace(name) {
+		case "stdout":
+			sinks = append(sinks, metricsink.NewStdout())
+		case "otlp":
+			if otlpEndpoint == "" {
+				return nil, fmt.Errorf("--otlp-endpoint is required when --sink includes otlp")
+			}
+			headers, err := metricsink.ParseHeaders(otlpHeadersStr)
+			if err != nil {
+				return nil, err
+			}
+			otlp, err := metricsink.NewOTLP(ctx, metricsink.OTLPConfig{
+				Endpoint: otlpEndpoint,
+				Protocol: otlpProtocol,
+				Insecure: otlpInsecure,
+				Headers:  headers,
+				OTGAPI:   otgURL,
+			})
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, otlp)
+		case "report":
+			batchInterval, err := time.ParseDuration(reportBatchIntervalStr)
+			if err != nil {
+				return nil, err
+			}
+			report, err := metricsink.NewReport(metricsink.ReportConfig{
+				Format:        reportFormat,
+				Output:        reportOutput,
+				BatchSize:     reportBatchSize,
+				BatchInterval: batchInterval,
+			})
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, report)
+		case "":
+			// allow trailing commas
+		default:
+			return nil, fmt.Errorf("unsupported sink: %s (want stdout, otlp, or report)", name)
+		}
+	}
+
+	return metricsink.NewMulti(sinks...), nil
 }
 
 func initOTG() (gosnappi.GosnappiApi, gosnappi.Config) {
@@ -115,7 +276,9 @@ func initOTG() (gosnappi.GosnappiApi, gosnappi.Config) {
 	api := gosnappi.NewApi()
 
 	// Set the transport protocol to either HTTP or GRPC
-	api.NewHttpTransport().SetLocation(otgURL).SetVerify(!otgIgnoreX509)
+	if err := setTransport(api); err != nil {
+		log.Fatal(err)
+	}
 
 	// Create a new traffic configuration that will be set on traffic generator
 	config := api.NewConfig()
@@ -132,22 +295,94 @@ func initOTG() (gosnappi.GosnappiApi, gosnappi.Config) {
 	return api, config
 }
 
-func runTraffic(api gosnappi.GosnappiApi, config gosnappi.Config) {
+// setTransport configures the gosnappi API handle to talk to the OTG
+// endpoint over either HTTP or gRPC, defaulting to whichever one --api's
+// scheme implies when --transport isn't set explicitly.
+func setTransport(api gosnappi.GosnappiApi) error {
+	transport := otgTransport
+	if transport == "" {
+		if strings.HasPrefix(otgURL, "grpc://") {
+			transport = "grpc"
+		} else {
+			transport = "http"
+		}
+	}
+
+	switch transport {
+	case "http":
+		api.NewHttpTransport().SetLocation(otgURL).SetVerify(!otgIgnoreX509)
+	case "grpc":
+		location := strings.TrimPrefix(otgURL, "grpc://")
+		grpcTimeout, err := time.ParseDuration(grpcTimeoutStr)
+		if err != nil {
+			return err
+		}
+		timeoutSec, err := grpcSeconds("grpc-timeout", grpcTimeout)
+		if err != nil {
+			return err
+		}
+		t := api.NewGrpcTransport().SetLocation(location).SetRequestTimeout(timeoutSec)
+		if otgIgnoreX509 {
+			t.SetVerify(false)
+		}
+		if grpcMaxMsgSize > 0 {
+			t.SetMaxMetricsMsgSize(int32(grpcMaxMsgSize))
+		}
+		if grpcKeepaliveStr != "" {
+			grpcKeepalive, err := time.ParseDuration(grpcKeepaliveStr)
+			if err != nil {
+				return err
+			}
+			keepaliveSec, err := grpcSeconds("grpc-keepalive", grpcKeepalive)
+			if err != nil {
+				return err
+			}
+			t.SetKeepAlivePing(keepaliveSec)
+		}
+	default:
+		return fmt.Errorf("unsupported transport: %s (want http or grpc)", transport)
+	}
+
+	return nil
+}
+
+// grpcSeconds converts d to the whole-second resolution the gosnappi gRPC
+// transport setters take, rejecting sub-second durations instead of
+// truncating them through int32(d.Seconds()) — which silently turns e.g.
+// --grpc-timeout=500ms into 0 (API-default/unbounded), contradicting what
+// the flag was set to.
+func grpcSeconds(flag string, d time.Duration) (int32, error) {
+	if d > 0 && d < time.Second {
+		return 0, fmt.Errorf("--%s: %s is below the 1s resolution the gRPC transport supports", flag, d)
+	}
+	return int32(d.Round(time.Second).Seconds()), nil
+}
+
+func runTraffic(ctx context.Context, api gosnappi.GosnappiApi, config gosnappi.Config, sink metricsink.Sink) {
+	ctx, runSpan := tracing.Tracer.Start(ctx, "otgen.run",
+		trace.WithAttributes(
+			attribute.String("otg.endpoint", otgURL),
+			attribute.String("otg.metrics_type", otgMetrics),
+			attribute.String("otg.pull_interval", otgPullInterval.String()),
+		),
+	)
+	defer runSpan.End()
+
 	// push traffic configuration to otgHost
 	log.Info("Applying OTG config...")
-	res, err := api.SetConfig(config)
+	res, err := applyConfig(ctx, api, config)
 	checkResponse(res, err)
 	log.Info("ready.")
 
 	// start transmitting configured flows
 	log.Info("Starting traffic...")
-	ts := api.NewTransmitState().SetState(gosnappi.TransmitStateState.START)
-	res, err = api.SetTransmitState(ts)
+	res, err = setTransmitState(ctx, api, gosnappi.TransmitStateState.START)
 	checkResponse(res, err)
 	log.Info("started...")
 
 	targetTx, trafficETA := calculateTrafficTargets(config)
 	log.Infof("Total packets to transmit: %d, ETA is: %s\n", targetTx, trafficETA)
+	runSpan.SetAttributes(attribute.Int64("otg.target_tx", targetTx))
 
 	// initialize flow metrics
 	req := api.NewMetricsRequest()
@@ -159,11 +394,10 @@ func runTraffic(api gosnappi.GosnappiApi, config gosnappi.Config) {
 	default:
 		req.Port()
 	}
-	metrics, err := api.GetMetrics(req)
+	metrics, err := pollMetrics(ctx, api, req, sink, 0)
 	if err != nil {
 		log.Fatal(err)
 	}
-	checkResponse(metrics, err)
 
 	start := time.Now()
 
@@ -182,18 +416,102 @@ func runTraffic(api gosnappi.GosnappiApi, config gosnappi.Config) {
 
 	for trafficRunning() {
 		time.Sleep(otgPullInterval)
-		metrics, err = api.GetMetrics(req)
-		checkResponse(metrics, err)
+		lastFramesTx := totalFramesTx(metrics)
+		metrics, err = pollMetrics(ctx, api, req, sink, lastFramesTx)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	// stop transmitting traffic
 	log.Info("Stopping traffic...")
-	ts = api.NewTransmitState().SetState(gosnappi.TransmitStateState.STOP)
-	res, err = api.SetTransmitState(ts)
+	res, err = setTransmitState(ctx, api, gosnappi.TransmitStateState.STOP)
 	checkResponse(res, err)
 	log.Info("stopped.")
 }
 
+// applyConfig wraps api.SetConfig in a span covering the config-apply phase.
+func applyConfig(ctx context.Context, api gosnappi.GosnappiApi, config gosnappi.Config) (gosnappi.ResponseWarning, error) {
+	_, span := tracing.Tracer.Start(ctx, "otgen.set_config")
+	defer span.End()
+
+	var res gosnappi.ResponseWarning
+	err := withRetry(ctx, func() error {
+		var err error
+		res, err = api.SetConfig(config)
+		return err
+	})
+	recordSpanErr(span, err)
+	return res, err
+}
+
+// setTransmitState wraps api.SetTransmitState in a span covering the start
+// or stop transmit phase.
+func setTransmitState(ctx context.Context, api gosnappi.GosnappiApi, state gosnappi.TransmitStateStateEnum) (gosnappi.ResponseWarning, error) {
+	_, span := tracing.Tracer.Start(ctx, "otgen.set_transmit_state", trace.WithAttributes(attribute.String("otg.transmit_state", string(state))))
+	defer span.End()
+
+	ts := api.NewTransmitState().SetState(state)
+	var res gosnappi.ResponseWarning
+	err := withRetry(ctx, func() error {
+		var err error
+		res, err = api.SetTransmitState(ts)
+		return err
+	})
+	recordSpanErr(span, err)
+	return res, err
+}
+
+// pollMetrics wraps a single api.GetMetrics poll in a span, records the
+// frames_tx delta since the previous poll as a span event, and exports the
+// resulting snapshot to sink.
+func pollMetrics(ctx context.Context, api gosnappi.GosnappiApi, req gosnappi.MetricsRequest, sink metricsink.Sink, lastFramesTx int64) (gosnappi.MetricsResponse, error) {
+	_, span := tracing.Tracer.Start(ctx, "otgen.get_metrics")
+	defer span.End()
+
+	var metrics gosnappi.MetricsResponse
+	err := withRetry(ctx, func() error {
+		var err error
+		metrics, err = api.GetMetrics(req)
+		return err
+	})
+	if err != nil {
+		recordSpanErr(span, err)
+		return metrics, err
+	}
+
+	framesTx := totalFramesTx(metrics)
+	span.AddEvent("frames_tx", trace.WithAttributes(
+		attribute.Int64("otg.frames_tx", framesTx),
+		attribute.Int64("otg.frames_tx_delta", framesTx-lastFramesTx),
+	))
+
+	exportMetrics(sink, metrics)
+	return metrics, nil
+}
+
+func totalFramesTx(mr gosnappi.MetricsResponse) int64 {
+	total := int64(0)
+	switch otgMetrics {
+	case "port":
+		for _, pm := range mr.PortMetrics().Items() {
+			total += pm.FramesTx()
+		}
+	case "flow":
+		for _, fm := range mr.FlowMetrics().Items() {
+			total += fm.FramesTx()
+		}
+	}
+	return total
+}
+
+func recordSpanErr(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
 func calculateTrafficTargets(config gosnappi.Config) (int64, time.Duration) {
 	// Initialize packet counts and rates per flow if they were provided as parameters. Calculate ETA
 	pktCountTotal := int64(0)
@@ -280,8 +598,6 @@ func checkResponse(res interface{}, err error) {
 		log.Fatal(err)
 	}
 	switch v := res.(type) {
-	case gosnappi.MetricsResponse:
-		printMetricsResponseRawJson(v)
 	case gosnappi.ResponseWarning:
 		for _, w := range v.Warnings() {
 			log.Info("WARNING:", w)
@@ -291,11 +607,10 @@ func checkResponse(res interface{}, err error) {
 	}
 }
 
-func printMetricsResponseRawJson(mr gosnappi.MetricsResponse) {
-	j, err := otgMetricsResponseToJson(mr.Msg())
-	if err == nil {
-		fmt.Println(string(j))
-	} else {
+// exportMetrics hands a polled MetricsResponse to every sink selected via
+// --sink (stdout JSON, OTLP, ...).
+func exportMetrics(sink metricsink.Sink, mr gosnappi.MetricsResponse) {
+	if err := sink.Export(context.Background(), mr); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 Open Traffic Generator
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions://
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGrpcSeconds(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       time.Duration
+		want    int32
+		wantErr bool
+	}{
+		{
+			name: "whole seconds pass through",
+			d:    30 * time.Second,
+			want: 30,
+		},
+		{
+			name: "zero is unbounded, not an error",
+			d:    0,
+			want: 0,
+		},
+		{
+			name:    "sub-second duration is rejected rather than truncated to 0",
+			d:       500 * time.Millisecond,
+			wantErr: true,
+		},
+		{
+			name:    "sub-second duration just under a second is rejected",
+			d:       999 * time.Millisecond,
+			wantErr: true,
+		},
+		{
+			name: "duration rounds to the nearest whole second",
+			d:    1500 * time.Millisecond,
+			want: 2,
+		},
+		{
+			name: "duration rounds down when closer to the lower second",
+			d:    1400 * time.Millisecond,
+			want: 1,
+		},
+		{
+			name: "exactly one second is accepted",
+			d:    time.Second,
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := grpcSeconds("grpc-timeout", tt.d)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("grpcSeconds(%q, %v) = %v, nil; want error", "grpc-timeout", tt.d, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("grpcSeconds(%q, %v) returned unexpected error: %v", "grpc-timeout", tt.d, err)
+			}
+			if got != tt.want {
+				t.Errorf("grpcSeconds(%q, %v) = %d, want %d", "grpc-timeout", tt.d, got, tt.want)
+			}
+		})
+	}
+}
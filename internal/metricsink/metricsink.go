@@ -0,0 +1,70 @@
+/*
+Copyright © 2022 Open Traffic Generator
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions://
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package metricsink provides destinations that otgen can report OTG
+// PortMetrics/FlowMetrics to, in addition to (or instead of) printing raw
+// JSON to stdout.
+package metricsink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-traffic-generator/snappi/gosnappi"
+)
+
+// Sink receives every MetricsResponse polled from the OTG API during a run.
+type Sink interface {
+	// Export is called once per poll interval with the latest metrics snapshot.
+	Export(ctx context.Context, mr gosnappi.MetricsResponse) error
+	// Close flushes and releases any resources held by the sink.
+	Close(ctx context.Context) error
+}
+
+// Multi fans a single metrics snapshot out to several sinks, so a run can
+// e.g. print to stdout and push to OTLP at the same time.
+type Multi struct {
+	sinks []Sink
+}
+
+// NewMulti returns a Sink that forwards to all of the given sinks in order.
+func NewMulti(sinks ...Sink) *Multi {
+	return &Multi{sinks: sinks}
+}
+
+func (m *Multi) Export(ctx context.Context, mr gosnappi.MetricsResponse) error {
+	for _, s := range m.sinks {
+		if err := s.Export(ctx, mr); err != nil {
+			return fmt.Errorf("sink export failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m *Multi) Close(ctx context.Context) error {
+	for _, s := range m.sinks {
+		if err := s.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
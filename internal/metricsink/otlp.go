@@ -0,0 +1,270 @@
+package metricsink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/open-traffic-generator/snappi/gosnappi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// OTLPConfig describes how to reach and authenticate to an OTLP metrics
+// collector.
+type OTLPConfig struct {
+	Endpoint string            // host:port of the OTLP collector
+	Protocol string            // "grpc" or "http/protobuf"
+	Insecure bool              // skip TLS when talking to the collector
+	Headers  map[string]string // extra headers/metadata sent with every export
+	OTGAPI   string            // OTG endpoint this run is driving, used as a resource attribute
+}
+
+// otlpInstruments holds the OTLP metric instruments that PortMetrics and
+// FlowMetrics fields are mapped onto. frames_tx/frames_rx/bytes_tx/bytes_rx
+// are cumulative counters already maintained by the OTG endpoint itself
+// (each poll returns the running total, not a delta), so they are modeled
+// as gauges rather than OTLP Sum instruments, which would double-count
+// them on every poll.
+type otlpInstruments struct {
+	portFramesTx instrument.Float64ObservableGauge
+	portFramesRx instrument.Float64ObservableGauge
+	portBytesTx  instrument.Float64ObservableGauge
+	portBytesRx  instrument.Float64ObservableGauge
+	portTxRate   instrument.Float64ObservableGauge
+	portRxRate   instrument.Float64ObservableGauge
+
+	flowFramesTx   instrument.Float64ObservableGauge
+	flowFramesRx   instrument.Float64ObservableGauge
+	flowLoss       instrument.Float64ObservableGauge
+	flowLatencyAvg instrument.Float64Histogram
+	flowLatencyMin instrument.Float64Histogram
+	flowLatencyMax instrument.Float64Histogram
+}
+
+// gaugeReading is the last-observed value for a gauge instrument, keyed by
+// the attribute set it was reported under. Observable gauges in the OTel
+// metric API are only sampled when the collector pulls, so the OTLP sink
+// keeps the latest value from each poll and replays it on demand.
+type gaugeReading struct {
+	value float64
+	attrs attribute.Set
+}
+
+// OTLP pushes PortMetrics/FlowMetrics snapshots to an OTLP metrics endpoint
+// as Gauge/Histogram instruments, so a run can be scraped into
+// Prometheus/Grafana/Tempo pipelines without post-processing JSON.
+type OTLP struct {
+	cfg      OTLPConfig
+	provider *sdkmetric.MeterProvider
+	inst     otlpInstruments
+
+	mu           sync.Mutex
+	portFramesTx map[string]gaugeReading
+	portFramesRx map[string]gaugeReading
+	portBytesTx  map[string]gaugeReading
+	portBytesRx  map[string]gaugeReading
+	portTxRate   map[string]gaugeReading
+	portRxRate   map[string]gaugeReading
+	flowFramesTx map[string]gaugeReading
+	flowFramesRx map[string]gaugeReading
+	flowLoss     map[string]gaugeReading
+}
+
+// NewOTLP dials the configured OTLP endpoint and returns a Sink that maps
+// OTG metrics to OTLP instruments.
+func NewOTLP(ctx context.Context, cfg OTLPConfig) (*OTLP, error) {
+	exporter, err := newOTLPExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String("otgen"),
+			attribute.String("otg.endpoint", cfg.OTGAPI),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("building OTLP resource: %w", err)
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+	)
+	meter := provider.Meter("github.com/open-traffic-generator/otgen")
+
+	o := &OTLP{
+		cfg:          cfg,
+		provider:     provider,
+		portFramesTx: map[string]gaugeReading{},
+		portFramesRx: map[string]gaugeReading{},
+		portBytesTx:  map[string]gaugeReading{},
+		portBytesRx:  map[string]gaugeReading{},
+		portTxRate:   map[string]gaugeReading{},
+		portRxRate:   map[string]gaugeReading{},
+		flowFramesTx: map[string]gaugeReading{},
+		flowFramesRx: map[string]gaugeReading{},
+		flowLoss:     map[string]gaugeReading{},
+	}
+
+	if o.inst, err = newOTLPInstruments(meter, o); err != nil {
+		return nil, fmt.Errorf("creating OTLP instruments: %w", err)
+	}
+
+	return o, nil
+}
+
+func newOTLPExporter(ctx context.Context, cfg OTLPConfig) (export.Exporter, error) {
+	switch cfg.Protocol {
+	case "grpc", "":
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case "http/protobuf":
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s (want grpc or http/protobuf)", cfg.Protocol)
+	}
+}
+
+func newOTLPInstruments(meter metric.Meter, o *OTLP) (otlpInstruments, error) {
+	var inst otlpInstruments
+	var err error
+
+	if inst.portFramesTx, err = meter.Float64ObservableGauge("otg.port.frames_tx"); err != nil {
+		return inst, err
+	}
+	if inst.portFramesRx, err = meter.Float64ObservableGauge("otg.port.frames_rx"); err != nil {
+		return inst, err
+	}
+	if inst.portBytesTx, err = meter.Float64ObservableGauge("otg.port.bytes_tx"); err != nil {
+		return inst, err
+	}
+	if inst.portBytesRx, err = meter.Float64ObservableGauge("otg.port.bytes_rx"); err != nil {
+		return inst, err
+	}
+	if inst.portTxRate, err = meter.Float64ObservableGauge("otg.port.frames_tx_rate"); err != nil {
+		return inst, err
+	}
+	if inst.portRxRate, err = meter.Float64ObservableGauge("otg.port.frames_rx_rate"); err != nil {
+		return inst, err
+	}
+	if inst.flowFramesTx, err = meter.Float64ObservableGauge("otg.flow.frames_tx"); err != nil {
+		return inst, err
+	}
+	if inst.flowFramesRx, err = meter.Float64ObservableGauge("otg.flow.frames_rx"); err != nil {
+		return inst, err
+	}
+	if inst.flowLoss, err = meter.Float64ObservableGauge("otg.flow.loss"); err != nil {
+		return inst, err
+	}
+	if inst.flowLatencyAvg, err = meter.Float64Histogram("otg.flow.latency.avg"); err != nil {
+		return inst, err
+	}
+	if inst.flowLatencyMin, err = meter.Float64Histogram("otg.flow.latency.min"); err != nil {
+		return inst, err
+	}
+	if inst.flowLatencyMax, err = meter.Float64Histogram("otg.flow.latency.max"); err != nil {
+		return inst, err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, obs metric.Observer) error {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		observeAll := func(inst instrument.Float64ObservableGauge, readings map[string]gaugeReading) {
+			for _, r := range readings {
+				obs.ObserveFloat64(inst, r.value, metric.WithAttributeSet(r.attrs))
+			}
+		}
+		observeAll(inst.portFramesTx, o.portFramesTx)
+		observeAll(inst.portFramesRx, o.portFramesRx)
+		observeAll(inst.portBytesTx, o.portBytesTx)
+		observeAll(inst.portBytesRx, o.portBytesRx)
+		observeAll(inst.portTxRate, o.portTxRate)
+		observeAll(inst.portRxRate, o.portRxRate)
+		observeAll(inst.flowFramesTx, o.flowFramesTx)
+		observeAll(inst.flowFramesRx, o.flowFramesRx)
+		observeAll(inst.flowLoss, o.flowLoss)
+		return nil
+	},
+		inst.portFramesTx, inst.portFramesRx, inst.portBytesTx, inst.portBytesRx,
+		inst.portTxRate, inst.portRxRate,
+		inst.flowFramesTx, inst.flowFramesRx, inst.flowLoss,
+	)
+	if err != nil {
+		return inst, err
+	}
+
+	return inst, nil
+}
+
+func (o *OTLP) Export(ctx context.Context, mr gosnappi.MetricsResponse) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, pm := range mr.PortMetrics().Items() {
+		attrs := attribute.NewSet(attribute.String("otg.port.name", pm.Name()))
+		o.portFramesTx[pm.Name()] = gaugeReading{value: float64(pm.FramesTx()), attrs: attrs}
+		o.portFramesRx[pm.Name()] = gaugeReading{value: float64(pm.FramesRx()), attrs: attrs}
+		o.portBytesTx[pm.Name()] = gaugeReading{value: float64(pm.BytesTx()), attrs: attrs}
+		o.portBytesRx[pm.Name()] = gaugeReading{value: float64(pm.BytesRx()), attrs: attrs}
+		o.portTxRate[pm.Name()] = gaugeReading{value: float64(pm.FramesTxRate()), attrs: attrs}
+		o.portRxRate[pm.Name()] = gaugeReading{value: float64(pm.FramesRxRate()), attrs: attrs}
+	}
+
+	for _, fm := range mr.FlowMetrics().Items() {
+		attrs := attribute.NewSet(attribute.String("otg.flow.name", fm.Name()))
+		o.flowFramesTx[fm.Name()] = gaugeReading{value: float64(fm.FramesTx()), attrs: attrs}
+		o.flowFramesRx[fm.Name()] = gaugeReading{value: float64(fm.FramesRx()), attrs: attrs}
+		o.flowLoss[fm.Name()] = gaugeReading{value: float64(fm.LossPct()), attrs: attrs}
+		o.inst.flowLatencyAvg.Record(ctx, float64(fm.Latency().Avg()), metric.WithAttributeSet(attrs))
+		o.inst.flowLatencyMin.Record(ctx, float64(fm.Latency().Min()), metric.WithAttributeSet(attrs))
+		o.inst.flowLatencyMax.Record(ctx, float64(fm.Latency().Max()), metric.WithAttributeSet(attrs))
+	}
+
+	return nil
+}
+
+func (o *OTLP) Close(ctx context.Context) error {
+	return o.provider.Shutdown(ctx)
+}
+
+// ParseHeaders turns a "key=val,key2=val2" flag value into a header map, the
+// same format used by `--otlp-headers`.
+func ParseHeaders(raw string) (map[string]string, error) {
+	headers := map[string]string{}
+	if raw == "" {
+		return headers, nil
+	}
+	for _, kv := range strings.Split(raw, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid header %q, want key=val", kv)
+		}
+		headers[parts[0]] = parts[1]
+	}
+	return headers, nil
+}
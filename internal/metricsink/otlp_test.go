@@ -0,0 +1,64 @@
+package metricsink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty string yields empty map",
+			raw:  "",
+			want: map[string]string{},
+		},
+		{
+			name: "single key=val pair",
+			raw:  "authorization=Bearer abc123",
+			want: map[string]string{"authorization": "Bearer abc123"},
+		},
+		{
+			name: "multiple pairs",
+			raw:  "x-api-key=secret,x-tenant=acme",
+			want: map[string]string{"x-api-key": "secret", "x-tenant": "acme"},
+		},
+		{
+			name: "value may itself contain an equals sign",
+			raw:  "authorization=Basic dXNlcjpwYXNz==",
+			want: map[string]string{"authorization": "Basic dXNlcjpwYXNz=="},
+		},
+		{
+			name:    "missing equals sign is an error",
+			raw:     "not-a-pair",
+			wantErr: true,
+		},
+		{
+			name:    "empty key is an error",
+			raw:     "=val",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHeaders(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseHeaders(%q) = %v, nil; want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHeaders(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHeaders(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
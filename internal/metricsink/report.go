@@ -0,0 +1,243 @@
+package metricsink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-traffic-generator/snappi/gosnappi"
+)
+
+// ReportConfig controls the columnar flow-metric reporter used for tests
+// with thousands of flows, where re-emitting a full MetricsResponse per
+// poll interval would dwarf the signal with redundant JSON.
+type ReportConfig struct {
+	Format        string        // "json", "ndjson", or "arrow"
+	Output        string        // "-" (stdout), "file://path", or "grpc://host:port"
+	BatchSize     int           // flush once this many changed flow records have accumulated
+	BatchInterval time.Duration // flush at least this often, even below BatchSize
+}
+
+// flowRecord is one row of the per-flow columnar buffer: name, counters,
+// loss, latency percentiles, and the timestamp of the poll it came from.
+type flowRecord struct {
+	Name       string  `json:"name"`
+	FramesTx   int64   `json:"frames_tx"`
+	FramesRx   int64   `json:"frames_rx"`
+	BytesTx    int64   `json:"bytes_tx"`
+	BytesRx    int64   `json:"bytes_rx"`
+	LossPct    float64 `json:"loss"`
+	LatencyAvg float64 `json:"latency_avg"`
+	LatencyMin float64 `json:"latency_min"`
+	LatencyMax float64 `json:"latency_max"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// countersUnchanged reports whether two readings of the same flow carry
+// identical counters and latency, meaning the flow was idle between polls
+// and a new record for it would be pure duplication. Latency is included
+// because it can still move (e.g. queueing jitter) while frame/byte
+// counters happen to land on the same values, and dropping that record
+// would silently lose the per-flow latency series this format exists to
+// carry.
+func (r flowRecord) countersUnchanged(other flowRecord) bool {
+	return r.FramesTx == other.FramesTx &&
+		r.FramesRx == other.FramesRx &&
+		r.BytesTx == other.BytesTx &&
+		r.BytesRx == other.BytesRx &&
+		r.LossPct == other.LossPct &&
+		r.LatencyAvg == other.LatencyAvg &&
+		r.LatencyMin == other.LatencyMin &&
+		r.LatencyMax == other.LatencyMax
+}
+
+// Report keeps an in-memory columnar buffer of per-flow metrics and flushes
+// only the flows that changed since the last batch, at --batch-size or
+// --batch-interval thresholds, to the configured destination.
+type Report struct {
+	cfg  ReportConfig
+	dest reportDestination
+
+	mu        sync.Mutex
+	last      map[string]flowRecord
+	pending   []flowRecord
+	lastFlush time.Time
+}
+
+// NewReport opens the destination described by cfg.Output and returns a
+// Sink that streams deduplicated flow-metric batches to it.
+func NewReport(cfg ReportConfig) (*Report, error) {
+	dest, err := newReportDestination(cfg.Output)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+	return &Report{
+		cfg:       cfg,
+		dest:      dest,
+		last:      map[string]flowRecord{},
+		lastFlush: time.Now(),
+	}, nil
+}
+
+func (r *Report) Export(ctx context.Context, mr gosnappi.MetricsResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, fm := range mr.FlowMetrics().Items() {
+		rec := flowRecord{
+			Name:       fm.Name(),
+			FramesTx:   fm.FramesTx(),
+			FramesRx:   fm.FramesRx(),
+			BytesTx:    fm.BytesTx(),
+			BytesRx:    fm.BytesRx(),
+			LossPct:    float64(fm.LossPct()),
+			LatencyAvg: float64(fm.Latency().Avg()),
+			LatencyMin: float64(fm.Latency().Min()),
+			LatencyMax: float64(fm.Latency().Max()),
+			Timestamp:  now.UnixNano(),
+		}
+		if prev, ok := r.last[rec.Name]; ok && prev.countersUnchanged(rec) {
+			continue // idle flow: nothing changed since the last batch, skip it
+		}
+		r.last[rec.Name] = rec
+		r.pending = append(r.pending, rec)
+	}
+
+	if len(r.pending) >= r.cfg.BatchSize || (len(r.pending) > 0 && now.Sub(r.lastFlush) >= r.cfg.BatchInterval) {
+		return r.flushLocked()
+	}
+	return nil
+}
+
+func (r *Report) flushLocked() error {
+	batch := r.pending
+	r.pending = nil
+	r.lastFlush = time.Now()
+	if len(batch) == 0 {
+		return nil
+	}
+	return r.dest.Write(r.cfg.Format, batch)
+}
+
+func (r *Report) Close(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.flushLocked(); err != nil {
+		return err
+	}
+	return r.dest.Close()
+}
+
+// reportDestination receives encoded batches from Report. Each Write call
+// owns the full encode+send of one batch so json/ndjson/arrow formats can
+// pick whatever framing suits them (array, newline-delimited, IPC stream).
+type reportDestination interface {
+	Write(format string, batch []flowRecord) error
+	Close() error
+}
+
+func newReportDestination(output string) (reportDestination, error) {
+	switch {
+	case output == "" || output == "-":
+		return &streamDestination{w: os.Stdout}, nil
+	case strings.HasPrefix(output, "file://"):
+		path := strings.TrimPrefix(output, "file://")
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening report output file: %w", err)
+		}
+		return &streamDestination{w: f, c: f}, nil
+	case strings.HasPrefix(output, "grpc://"):
+		return newGrpcDestination(strings.TrimPrefix(output, "grpc://"))
+	default:
+		return nil, fmt.Errorf("unsupported report output: %s (want -, file://path, or grpc://host:port)", output)
+	}
+}
+
+// streamDestination writes encoded batches to an io.Writer: stdout or a
+// local file. For "arrow" format it keeps one arrowStreamEncoder for the
+// life of the destination, so the schema is declared once rather than per
+// flush; each batch still carries its own "name" dictionary.
+type streamDestination struct {
+	w io.Writer
+	c io.Closer
+
+	arrow *arrowStreamEncoder
+}
+
+func (d *streamDestination) Write(format string, batch []flowRecord) error {
+	if format == "arrow" {
+		if d.arrow == nil {
+			d.arrow = newArrowStreamEncoder(d.w)
+		}
+		return d.arrow.Write(batch)
+	}
+	encoded, err := encodeBatch(format, batch)
+	if err != nil {
+		return err
+	}
+	_, err = d.w.Write(encoded)
+	return err
+}
+
+func (d *streamDestination) Close() error {
+	if d.arrow != nil {
+		if err := d.arrow.Close(); err != nil {
+			return err
+		}
+	}
+	if d.c != nil {
+		return d.c.Close()
+	}
+	return nil
+}
+
+// encodeBatch renders a batch of flow records in the requested
+// --report-format. It is used by destinations that send one self-contained
+// message per batch (e.g. grpcDestination); streamDestination instead keeps
+// a long-lived arrowStreamEncoder so "arrow" batches share one schema
+// instead of each going through here.
+func encodeBatch(format string, batch []flowRecord) ([]byte, error) {
+	switch format {
+	case "", "json":
+		j, err := json.Marshal(batch)
+		if err != nil {
+			return nil, err
+		}
+		return append(j, '\n'), nil
+	case "ndjson":
+		return encodeNDJSON(batch)
+	case "arrow":
+		return encodeArrowIPCBatch(batch)
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s (want json, ndjson, or arrow)", format)
+	}
+}
+
+// encodeNDJSON gzip-compresses one JSON object per line, the cheapest way
+// to shrink a long run's worth of mostly-duplicate flow records.
+func encodeNDJSON(batch []flowRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, rec := range batch {
+		if err := enc.Encode(rec); err != nil {
+			return nil, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
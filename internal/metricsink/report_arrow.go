@@ -0,0 +1,107 @@
+package metricsink
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// reportSchema is the Arrow schema for a batch of flow records. Flow name
+// is dictionary-encoded; each record batch carries a dictionary built from
+// that batch's names only.
+var reportSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "name", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}},
+	{Name: "frames_tx", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "frames_rx", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "bytes_tx", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "bytes_rx", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "loss", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "latency_avg", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "latency_min", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "latency_max", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "timestamp", Type: arrow.PrimitiveTypes.Int64},
+}, nil)
+
+// arrowStreamEncoder writes every batch for the life of a destination
+// through a single ipc.Writer, so the schema is written once at the start of
+// the stream. Each Write builds the "name" dictionary afresh from that
+// batch's records; entries from earlier batches are not carried over.
+type arrowStreamEncoder struct {
+	pool *memory.GoAllocator
+	w    *ipc.Writer
+}
+
+// newArrowStreamEncoder opens a long-lived Arrow IPC stream on w. Callers
+// must call Close once the destination is done to flush the trailing
+// end-of-stream marker.
+func newArrowStreamEncoder(w io.Writer) *arrowStreamEncoder {
+	pool := memory.NewGoAllocator()
+	return &arrowStreamEncoder{
+		pool: pool,
+		w:    ipc.NewWriter(w, ipc.WithSchema(reportSchema), ipc.WithAllocator(pool)),
+	}
+}
+
+// Write appends one record batch to the open IPC stream.
+func (e *arrowStreamEncoder) Write(batch []flowRecord) error {
+	b := array.NewRecordBuilder(e.pool, reportSchema)
+	defer b.Release()
+
+	nameBuilder := b.Field(0).(*array.BinaryDictionaryBuilder)
+	framesTx := b.Field(1).(*array.Int64Builder)
+	framesRx := b.Field(2).(*array.Int64Builder)
+	bytesTx := b.Field(3).(*array.Int64Builder)
+	bytesRx := b.Field(4).(*array.Int64Builder)
+	loss := b.Field(5).(*array.Float64Builder)
+	latencyAvg := b.Field(6).(*array.Float64Builder)
+	latencyMin := b.Field(7).(*array.Float64Builder)
+	latencyMax := b.Field(8).(*array.Float64Builder)
+	timestamp := b.Field(9).(*array.Int64Builder)
+
+	for _, rec := range batch {
+		if err := nameBuilder.AppendString(rec.Name); err != nil {
+			return err
+		}
+		framesTx.Append(rec.FramesTx)
+		framesRx.Append(rec.FramesRx)
+		bytesTx.Append(rec.BytesTx)
+		bytesRx.Append(rec.BytesRx)
+		loss.Append(rec.LossPct)
+		latencyAvg.Append(rec.LatencyAvg)
+		latencyMin.Append(rec.LatencyMin)
+		latencyMax.Append(rec.LatencyMax)
+		timestamp.Append(rec.Timestamp)
+	}
+
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	return e.w.Write(rec)
+}
+
+// Close flushes the end-of-stream marker for the IPC stream. It does not
+// close the underlying io.Writer.
+func (e *arrowStreamEncoder) Close() error {
+	return e.w.Close()
+}
+
+// encodeArrowIPCBatch encodes one batch as a self-contained Arrow IPC
+// stream. It exists for destinations like grpcDestination, where each
+// flush is its own discrete unary call and there is no long-lived stream
+// to hang an arrowStreamEncoder off of, so every call
+// necessarily re-declares the schema and "name" dictionary.
+func encodeArrowIPCBatch(batch []flowRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := newArrowStreamEncoder(&buf)
+	if err := enc.Write(batch); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package metricsink
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// reportSinkMethod is the unary RPC a --report-output=grpc://host:port
+// destination is expected to implement: accept one pre-encoded batch per
+// call and acknowledge it. otgen ships no server for it; it's meant to be
+// implemented by whatever downstream collector a user points otgen at.
+const reportSinkMethod = "/otgen.report.v1.ReportSink/Export"
+
+// grpcDestination streams encoded report batches to a user-run collector
+// over a plain gRPC connection, one unary call per flush. The batch is
+// already fully encoded in the requested --report-format, so the call
+// passes it through as opaque bytes via rawCodec rather than requiring a
+// generated protobuf message.
+type grpcDestination struct {
+	conn *grpc.ClientConn
+}
+
+func newGrpcDestination(target string) (*grpcDestination, error) {
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dialing report sink %s: %w", target, err)
+	}
+	return &grpcDestination{conn: conn}, nil
+}
+
+func (d *grpcDestination) Write(format string, batch []flowRecord) error {
+	encoded, err := encodeBatch(format, batch)
+	if err != nil {
+		return err
+	}
+	var resp []byte
+	return d.conn.Invoke(context.Background(), reportSinkMethod, &encoded, &resp, grpc.ForceCodec(rawCodec{}))
+}
+
+func (d *grpcDestination) Close() error {
+	return d.conn.Close()
+}
+
+// rawCodec passes gRPC message bytes through unchanged, since ReportSink's
+// wire contract is just "here's an encoded batch" rather than a generated
+// protobuf message.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unsupported type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unsupported type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "raw" }
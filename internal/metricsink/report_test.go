@@ -0,0 +1,92 @@
+package metricsink
+
+import "testing"
+
+func TestFlowRecordCountersUnchanged(t *testing.T) {
+	base := flowRecord{
+		Name:       "f1",
+		FramesTx:   100,
+		FramesRx:   100,
+		BytesTx:    6400,
+		BytesRx:    6400,
+		LossPct:    0,
+		LatencyAvg: 1.5,
+		LatencyMin: 1.0,
+		LatencyMax: 2.0,
+		Timestamp:  1,
+	}
+
+	tests := []struct {
+		name  string
+		other flowRecord
+		want  bool
+	}{
+		{
+			name:  "identical reading is unchanged",
+			other: base,
+			want:  true,
+		},
+		{
+			name: "only the timestamp differs",
+			other: func() flowRecord {
+				r := base
+				r.Timestamp = 2
+				return r
+			}(),
+			want: true,
+		},
+		{
+			name: "frames_tx moved",
+			other: func() flowRecord {
+				r := base
+				r.FramesTx = 101
+				return r
+			}(),
+			want: false,
+		},
+		{
+			name: "loss moved",
+			other: func() flowRecord {
+				r := base
+				r.LossPct = 0.1
+				return r
+			}(),
+			want: false,
+		},
+		{
+			name: "counters steady but latency_avg moved",
+			other: func() flowRecord {
+				r := base
+				r.LatencyAvg = 1.8
+				return r
+			}(),
+			want: false,
+		},
+		{
+			name: "counters steady but latency_min moved",
+			other: func() flowRecord {
+				r := base
+				r.LatencyMin = 0.9
+				return r
+			}(),
+			want: false,
+		},
+		{
+			name: "counters steady but latency_max moved",
+			other: func() flowRecord {
+				r := base
+				r.LatencyMax = 2.5
+				return r
+			}(),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.countersUnchanged(tt.other); got != tt.want {
+				t.Errorf("countersUnchanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
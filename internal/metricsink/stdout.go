@@ -0,0 +1,34 @@
+package metricsink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-traffic-generator/snappi/gosnappi"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Stdout prints each MetricsResponse as raw JSON, matching otgen's historical
+// (pre-sink) output format.
+type Stdout struct{}
+
+// NewStdout returns a Sink that writes raw JSON to stdout.
+func NewStdout() *Stdout {
+	return &Stdout{}
+}
+
+func (s *Stdout) Export(ctx context.Context, mr gosnappi.MetricsResponse) error {
+	// MetricsResponse is a protobuf oneof over PortMetricsList/FlowMetricsList;
+	// protoc-gen-go represents oneofs via an unexported wrapper field that
+	// encoding/json silently drops, so marshal through protojson instead.
+	j, err := protojson.Marshal(mr.Msg())
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(j))
+	return nil
+}
+
+func (s *Stdout) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2022 Open Traffic Generator
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions://
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package retry wraps transient OTG API calls with exponential backoff, so a
+// long soak test doesn't abort just because the endpoint briefly hiccuped.
+package retry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// Config controls the backoff schedule and which errors are worth retrying.
+type Config struct {
+	MaxElapsedTime  time.Duration // give up and return the last error after this much wall-clock time
+	InitialInterval time.Duration // delay before the first retry
+	Multiplier      float64       // factor the delay grows by after each retry
+	RetryOn         []string      // classes of error worth retrying: "deadline", "unavailable", "connrefused"
+}
+
+// OnRetry is called once per retry attempt, before the backoff sleep, with
+// the 1-based attempt number and the error that triggered the retry.
+type OnRetry func(attempt int, err error)
+
+// Do calls fn, retrying with exponential backoff while the returned error is
+// retryable per cfg.RetryOn, until it succeeds, a non-retryable error is
+// returned, or cfg.MaxElapsedTime is exceeded. A non-retryable error is
+// returned at once; otherwise the last error fn returned is returned once the
+// budget is exhausted.
+func Do(ctx context.Context, cfg Config, fn func() error, onRetry OnRetry) error {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = cfg.InitialInterval
+	bo.Multiplier = cfg.Multiplier
+	bo.MaxElapsedTime = cfg.MaxElapsedTime
+
+	attempt := 0
+	var lastErr error
+
+	operation := func() error {
+		lastErr = fn()
+		if lastErr == nil {
+			return nil
+		}
+		if !retryable(lastErr, cfg.RetryOn) {
+			return backoff.Permanent(lastErr)
+		}
+		return lastErr
+	}
+
+	// RetryNotify only calls notify when another attempt will follow, so the
+	// final error after the budget runs out is not reported as a retry.
+	notify := func(err error, _ time.Duration) {
+		attempt++
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+	}
+
+	if err := backoff.RetryNotify(operation, backoff.WithContext(bo, ctx), notify); err != nil {
+		return lastErr
+	}
+	return nil
+}
+
+// retryable classifies err against the requested retry classes. An empty
+// classes list matches nothing, which makes Do behave like a single attempt.
+func retryable(err error, classes []string) bool {
+	msg := strings.ToLower(err.Error())
+	for _, class := range classes {
+		switch strings.TrimSpace(strings.ToLower(class)) {
+		case "deadline":
+			if strings.Contains(msg, "deadline exceeded") || strings.Contains(msg, "timeout") {
+				return true
+			}
+		case "unavailable":
+			if strings.Contains(msg, "unavailable") {
+				return true
+			}
+		case "connrefused":
+			if strings.Contains(msg, "connection refused") {
+				return true
+			}
+		}
+	}
+	return false
+}
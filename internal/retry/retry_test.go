@@ -0,0 +1,95 @@
+/*
+Copyright © 2022 Open Traffic Generator
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions://
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		classes []string
+		want    bool
+	}{
+		{
+			name:    "deadline exceeded matches deadline class",
+			err:     errors.New("context deadline exceeded"),
+			classes: []string{"deadline"},
+			want:    true,
+		},
+		{
+			name:    "wrapped deadline exceeded still matches",
+			err:     fmt.Errorf("polling metrics: %w", errors.New("context deadline exceeded")),
+			classes: []string{"deadline"},
+			want:    true,
+		},
+		{
+			name:    "timeout wording matches deadline class",
+			err:     errors.New("rpc error: code = DeadlineExceeded desc = timeout waiting for response"),
+			classes: []string{"deadline"},
+			want:    true,
+		},
+		{
+			name:    "unavailable matches unavailable class regardless of case",
+			err:     errors.New("rpc error: code = Unavailable desc = transport is closing"),
+			classes: []string{"Unavailable"},
+			want:    true,
+		},
+		{
+			name:    "connection refused matches connrefused class",
+			err:     errors.New("dial tcp 127.0.0.1:443: connect: connection refused"),
+			classes: []string{"connrefused"},
+			want:    true,
+		},
+		{
+			name:    "unrelated error does not match requested class",
+			err:     errors.New("rpc error: code = InvalidArgument desc = bad flow name"),
+			classes: []string{"unavailable", "deadline"},
+			want:    false,
+		},
+		{
+			name:    "empty class list never retries",
+			err:     errors.New("context deadline exceeded"),
+			classes: nil,
+			want:    false,
+		},
+		{
+			name:    "class names tolerate surrounding whitespace",
+			err:     errors.New("connection refused"),
+			classes: []string{" connrefused "},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err, tt.classes); got != tt.want {
+				t.Errorf("retryable(%q, %v) = %v, want %v", tt.err, tt.classes, got, tt.want)
+			}
+		})
+	}
+}
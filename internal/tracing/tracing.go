@@ -0,0 +1,137 @@
+/*
+Copyright © 2022 Open Traffic Generator
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions://
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package tracing wires otgen's run lifecycle into OpenTelemetry distributed
+// tracing, so a run's spans can be correlated with DUT-side traces already
+// produced by a user's network stack.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is the tracer otgen uses to create spans for the run lifecycle.
+// It is a package-level no-op tracer until Init configures a real exporter,
+// so callers can create spans unconditionally without checking whether
+// tracing was enabled.
+var Tracer trace.Tracer = otel.Tracer("github.com/open-traffic-generator/otgen")
+
+// Config controls whether and how otgen exports traces via OTLP.
+//
+// Endpoint/Protocol map to --otel-traces-endpoint/--otel-traces-protocol.
+// When either is empty, Init honors the standard OTEL_EXPORTER_OTLP_*
+// environment variables instead, so otgen can slot into an existing
+// collector pipeline with zero flags.
+type Config struct {
+	Endpoint string // OTLP traces collector endpoint, e.g. localhost:4317
+	Protocol string // "grpc" or "http/protobuf"; empty defers to OTEL_EXPORTER_OTLP_*_PROTOCOL
+}
+
+// Init configures the global tracer provider according to cfg and the
+// standard OTEL_EXPORTER_OTLP_* environment variables. It returns a shutdown
+// function that flushes and closes the exporter; callers should defer it.
+// If neither cfg.Endpoint nor OTEL_EXPORTER_OTLP_ENDPOINT/OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// is set, Init is a no-op and returns a shutdown function that does nothing.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" && !otlpEnvConfigured() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceNameKey.String("otgen")))
+	if err != nil {
+		return nil, fmt.Errorf("building OTLP resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	Tracer = provider.Tracer("github.com/open-traffic-generator/otgen")
+
+	return provider.Shutdown, nil
+}
+
+func newTraceExporter(ctx context.Context, cfg Config) (*otlptrace.Exporter, error) {
+	protocol := resolveProtocol(cfg.Protocol)
+	switch protocol {
+	case "http/protobuf":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "grpc":
+		var opts []otlptracegrpc.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP traces protocol: %s (want grpc or http/protobuf)", protocol)
+	}
+}
+
+// resolveProtocol picks the OTLP traces wire protocol, preferring flag over
+// the standard OTEL_EXPORTER_OTLP_* env vars over the package default:
+// --otel-traces-protocol > OTEL_EXPORTER_OTLP_TRACES_PROTOCOL >
+// OTEL_EXPORTER_OTLP_PROTOCOL > "grpc".
+func resolveProtocol(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"); v != "" {
+		return v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); v != "" {
+		return v
+	}
+	return "grpc"
+}
+
+func otlpEnvConfigured() bool {
+	for _, k := range []string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	} {
+		if v, ok := os.LookupEnv(k); ok && v != "" {
+			return true
+		}
+	}
+	return false
+}